@@ -15,6 +15,7 @@ Version:
 Type Cache:
 	func New(defaultExpiration, cleanupInterval time.Duration) *Cache
 	func NewFrom(defaultExpiration, cleanupInterval time.Duration, items map[string]Item) *Cache
+	func NewSharded(defaultExpiration, cleanupInterval time.Duration, shards int) *Cache
 	func (c Cache) Add(k string, x interface{}, d time.Duration) error
 	func (c Cache) Get(k string) (interface{}, bool)
 	func (c Cache) GetWithExpiration(k string) (interface{}, time.Time, bool)
@@ -27,12 +28,36 @@ Type Cache:
 	func (c Cache) Items() map[string]Item
 	func (c Cache) Replace(k string, x interface{}, d time.Duration) error
 	func (c Cache) OnEvicted(f func(string, interface{}))
+	func (c Cache) Save(w io.Writer) error
+	func (c Cache) SaveFile(fname string) error
+	func (c Cache) Load(r io.Reader) error
+	func (c Cache) LoadFile(fname string) error
+	func Register(value interface{})
+	func (c Cache) Increment(k string, n int64) error
+	func (c Cache) IncrementFloat(k string, n float64) error
+	func (c Cache) Decrement(k string, n int64) error
+	func (c Cache) DecrementFloat(k string, n float64) error
+	func NewWithPolicy(defaultExpiration, cleanupInterval time.Duration, maxItems int, policy EvictionPolicy) *Cache
+	func (c Cache) GetOrLoad(k string, d time.Duration, loader func(key string) (interface{}, error)) (interface{}, error)
+	func (c Cache) GetOrLoadContext(ctx context.Context, k string, d time.Duration, loader func(key string) (interface{}, error)) (interface{}, error)
+	func (c Cache) Stats() Stats
+
+Subpackages:
+	cache/expvar    把 Stats() 发布到 expvar
+	cache/promcache 把 Stats() 包装成 prometheus.Collector
 */
 
 import (
+	"context"
+	"encoding/gob"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -67,9 +92,139 @@ type cache struct {
 	mu                sync.RWMutex              // 读写锁
 	onEvicted         func(string, interface{}) // 这里是为删除的时候加一个钩子方法，即删除前调用这个方法（可选）
 	janitor           *janitor
+
+	// shards 非 nil 时，当前 cache 只是一个路由层：真正的数据分布在 shards 里，
+	// 所有操作按 key 的哈希转发到对应分片，避免单把 mu 成为高并发下的瓶颈。
+	shards []*cache
+	mask   uint32
+
+	// maxItems > 0 且 policy 非 nil 时，cache 是有界大小的：写入新 key 导致
+	// 元素个数超过 maxItems 时会调用 policy.Evict() 选出一个 key 淘汰掉。
+	maxItems int
+	policy   EvictionPolicy
+
+	// inflight 用于 GetOrLoad：同一个 key 同时发生的多次未命中只会真正调用一次
+	// loader，其余调用者等待并共享结果
+	inflightMu sync.Mutex
+	inflight   map[string]*inflightCall
+
+	// 以下计数器只用原子操作读写，不受 mu 保护，方便在不持锁的情况下做 Stats()
+	hits              int64
+	misses            int64
+	evictions         int64 // 过期淘汰 + 淘汰策略触发的淘汰，不含显式 Delete
+	insertions        int64
+	sweeps            int64
+	sweepNanos        int64 // 所有 DeleteExpired 调用累计花费的时间
+	sweepItemsRemoved int64 // 所有 DeleteExpired 调用累计删除的 item 数
+
+	// sweepHistogram[i] 统计耗时落在 (sweepBucketBounds[i-1], sweepBucketBounds[i]]
+	// 区间内的 DeleteExpired 调用次数（第 0 个桶下界是 0），最后一项是超过
+	// sweepBucketBounds 最大值的调用次数，配合 sweepNanos/sweepItemsRemoved 的
+	// 累计值，让操作者既能看到平均耗时，也能看到耗时分布，用来判断 cleanupInterval
+	// 是不是设置得合适。
+	sweepHistogram [len(sweepBucketBounds) + 1]int64
+}
+
+// sweepBucketBounds 是 sweep 耗时直方图的桶上界
+var sweepBucketBounds = [...]time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+}
+
+// recordSweepDuration 把一次 DeleteExpired 调用的耗时计入对应的直方图桶
+func recordSweepDuration(hist *[len(sweepBucketBounds) + 1]int64, d time.Duration) {
+	for i, bound := range sweepBucketBounds {
+		if d <= bound {
+			atomic.AddInt64(&hist[i], 1)
+			return
+		}
+	}
+	atomic.AddInt64(&hist[len(sweepBucketBounds)], 1)
+}
+
+// SweepHistogram 是 DeleteExpired 调用耗时的分布统计。Bounds[i] 是第 i 个桶的
+// 耗时上界，Counts[i] 是耗时落在该桶里的 sweep 次数；Counts 比 Bounds 多一项，
+// 最后一项统计所有超过 Bounds 里最大值的 sweep 次数。
+type SweepHistogram struct {
+	Bounds []time.Duration
+	Counts []int64
+}
+
+func (c *cache) sweepHistogramSnapshot() SweepHistogram {
+	counts := make([]int64, len(c.sweepHistogram))
+	for i := range counts {
+		counts[i] = atomic.LoadInt64(&c.sweepHistogram[i])
+	}
+	return SweepHistogram{Bounds: sweepBucketBounds[:], Counts: counts}
+}
+
+// Stats 是某个时刻的 cache 统计快照，通过 Stats() 获取。分片 cache 的 Stats()
+// 会把各个分片的数据加总后返回。
+type Stats struct {
+	Hits                   int64
+	Misses                 int64
+	Evictions              int64
+	Insertions             int64
+	Sweeps                 int64
+	SweepDuration          time.Duration
+	SweepItemsRemoved      int64
+	SweepDurationHistogram SweepHistogram
+}
+
+// Stats 返回当前的命中率等统计信息快照
+func (c *cache) Stats() Stats {
+	if c.shards != nil {
+		var s Stats
+		hist := SweepHistogram{Bounds: sweepBucketBounds[:], Counts: make([]int64, len(sweepBucketBounds)+1)}
+		for _, sh := range c.shards {
+			ss := sh.Stats()
+			s.Hits += ss.Hits
+			s.Misses += ss.Misses
+			s.Evictions += ss.Evictions
+			s.Insertions += ss.Insertions
+			s.Sweeps += ss.Sweeps
+			s.SweepDuration += ss.SweepDuration
+			s.SweepItemsRemoved += ss.SweepItemsRemoved
+			for i, n := range ss.SweepDurationHistogram.Counts {
+				hist.Counts[i] += n
+			}
+		}
+		s.SweepDurationHistogram = hist
+		return s
+	}
+	return Stats{
+		Hits:                   atomic.LoadInt64(&c.hits),
+		Misses:                 atomic.LoadInt64(&c.misses),
+		Evictions:              atomic.LoadInt64(&c.evictions),
+		Insertions:             atomic.LoadInt64(&c.insertions),
+		Sweeps:                 atomic.LoadInt64(&c.sweeps),
+		SweepDuration:          time.Duration(atomic.LoadInt64(&c.sweepNanos)),
+		SweepItemsRemoved:      atomic.LoadInt64(&c.sweepItemsRemoved),
+		SweepDurationHistogram: c.sweepHistogramSnapshot(),
+	}
+}
+
+// inflightCall 代表一次正在进行中的 loader 调用
+type inflightCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
 }
 
 func (c *cache) Objects() map[string]interface{} {
+	if c.shards != nil {
+		m := make(map[string]interface{})
+		for _, s := range c.shards {
+			for k, v := range s.Objects() {
+				m[k] = v
+			}
+		}
+		return m
+	}
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	m := make(map[string]interface{}, len(c.items))
@@ -86,6 +241,15 @@ func (c *cache) Objects() map[string]interface{} {
 }
 
 func (c *cache) Items() map[string]Item {
+	if c.shards != nil {
+		m := make(map[string]Item)
+		for _, s := range c.shards {
+			for k, v := range s.Items() {
+				m[k] = v
+			}
+		}
+		return m
+	}
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	m := make(map[string]Item, len(c.items))
@@ -137,7 +301,77 @@ func NewFrom(defaultExpiration, cleanupInterval time.Duration, items map[string]
 	return newCacheWithJanitor(defaultExpiration, cleanupInterval, items)
 }
 
+// nextPowerOfTwo 将 n 向上取整到最近的 2 的幂，方便用位运算做掩码取模
+func nextPowerOfTwo(n int) uint32 {
+	p := uint32(1)
+	for p < uint32(n) {
+		p <<= 1
+	}
+	return p
+}
+
+// shardIndex 用 fnv-1a 计算 key 的分片下标
+func shardIndex(k string, mask uint32) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(k))
+	return h.Sum32() & mask
+}
+
+// shardFor 返回 key 所属的分片，只有 c.shards 非 nil 时才可调用
+func (c *cache) shardFor(k string) *cache {
+	return c.shards[shardIndex(k, c.mask)]
+}
+
+// NewSharded 返回一个分片 cache：把 key 按 fnv-1a 哈希 + 掩码分散到 shards 个独立的
+// cache 分片上，每个分片各自持有一把 RWMutex 和一个 janitor，用来缓解单把全局锁在
+// 大 key 量、高并发场景下的竞争。shards 会被向上取整到最近的 2 的幂。
+// 对外暴露的 API 和 New/NewFrom 返回的 *Cache 完全一致。
+func NewSharded(defaultExpiration, cleanupInterval time.Duration, shards int) *Cache {
+	if shards < 1 {
+		shards = 1
+	}
+	n := nextPowerOfTwo(shards)
+	cs := make([]*cache, n)
+	for i := range cs {
+		cs[i] = newCache(defaultExpiration, make(map[string]Item))
+		if cleanupInterval > 0 {
+			runJanitor(cs[i], cleanupInterval)
+		}
+	}
+	root := &cache{
+		defaultExpiration: defaultExpiration,
+		shards:            cs,
+		mask:              n - 1,
+	}
+	C := &Cache{root}
+	if cleanupInterval > 0 {
+		runtime.SetFinalizer(C, stopJanitor)
+	}
+	return C
+}
+
+// NewWithPolicy 返回一个有界大小的 cache：一旦元素个数超过 maxItems，写入新 key
+// 就会触发 policy.Evict()，选出一个 key 淘汰掉，并像超时淘汰一样触发 onEvicted
+// 钩子。maxItems <= 0 时等价于不限制大小（只有 policy 记录命中/写入，但从不淘汰）。
+// 见 EvictionPolicy 以及内置的 LRU / LFU / TinyLFU 实现。
+func NewWithPolicy(defaultExpiration, cleanupInterval time.Duration, maxItems int, policy EvictionPolicy) *Cache {
+	items := make(map[string]Item)
+	c := newCache(defaultExpiration, items)
+	c.maxItems = maxItems
+	c.policy = policy
+	C := &Cache{c}
+	if cleanupInterval > 0 {
+		runJanitor(c, cleanupInterval)
+		runtime.SetFinalizer(C, stopJanitor)
+	}
+	return C
+}
+
 func (c *cache) Set(k string, x interface{}, d time.Duration) {
+	if c.shards != nil {
+		c.shardFor(k).Set(k, x, d)
+		return
+	}
 	var e int64
 	if d == DefaultExpiration {
 		d = c.defaultExpiration
@@ -150,10 +384,12 @@ func (c *cache) Set(k string, x interface{}, d time.Duration) {
 	// 据说defer会多耗费~200纳秒
 	defer c.mu.Unlock() // 写解锁
 
+	_, existed := c.items[k]
 	c.items[k] = Item{
 		Object:     x,
 		Expiration: e,
 	}
+	c.afterInsert(k, existed)
 }
 
 func (c *cache) set(k string, x interface{}, d time.Duration) {
@@ -178,6 +414,9 @@ func (c *cache) SetDefault(k string, x interface{}) {
 
 // 添加一个 cache 中本来就没有的或者存在但是过期了的 item
 func (c *cache) Add(k string, x interface{}, d time.Duration) error {
+	if c.shards != nil {
+		return c.shardFor(k).Add(k, x, d)
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -187,11 +426,15 @@ func (c *cache) Add(k string, x interface{}, d time.Duration) error {
 	}
 	// 小写的 set 没有写锁定
 	c.set(k, x, d)
+	c.afterInsert(k, false)
 	return nil
 }
 
 // 就是替换 cache 中已存在的 item，没有就报错
 func (c *cache) Replace(k string, x interface{}, d time.Duration) error {
+	if c.shards != nil {
+		return c.shardFor(k).Replace(k, x, d)
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -200,47 +443,171 @@ func (c *cache) Replace(k string, x interface{}, d time.Duration) error {
 		return fmt.Errorf("Item %s doesn't exist", k)
 	}
 	c.set(k, x, d)
+	c.afterInsert(k, true)
 	return nil
 }
 
+// afterInsert 在持有写锁的前提下，把这次写入上报给淘汰策略；existed 为 false 时
+// 说明是全新的 key，如果因此超出了 MaxItems，就淘汰一个 key，并像 janitor 淘汰
+// 过期 item 一样触发 onEvicted 钩子
+func (c *cache) afterInsert(k string, existed bool) {
+	if !existed {
+		atomic.AddInt64(&c.insertions, 1)
+	}
+	if c.policy == nil {
+		return
+	}
+	if existed {
+		c.policy.RecordAccess(k)
+		return
+	}
+	c.policy.RecordInsert(k)
+	for c.maxItems > 0 && len(c.items) > c.maxItems {
+		victim, ok := c.policy.Evict()
+		if !ok {
+			break
+		}
+		_, present := c.items[victim]
+		v, evicted := c.delete(victim)
+		c.policy.Remove(victim)
+		if present {
+			atomic.AddInt64(&c.evictions, 1)
+		}
+		if evicted {
+			c.onEvicted(victim, v)
+		}
+	}
+}
+
 func (c *cache) Get(k string) (interface{}, bool) {
+	if c.shards != nil {
+		return c.shardFor(k).Get(k)
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	item, found := c.items[k]
 	if !found {
+		atomic.AddInt64(&c.misses, 1)
 		return nil, false
 	}
 	if item.Expiration > 0 {
 		if time.Now().UnixNano() > item.Expiration {
+			atomic.AddInt64(&c.misses, 1)
 			return nil, false
 		}
 	}
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	atomic.AddInt64(&c.hits, 1)
 	return item.Object, true
 }
 
 // 不仅返回 item 的值，还返回过期时间
 func (c *cache) GetWithExpiration(k string) (interface{}, time.Time, bool) {
+	if c.shards != nil {
+		return c.shardFor(k).GetWithExpiration(k)
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	item, found := c.items[k]
 	if !found {
+		atomic.AddInt64(&c.misses, 1)
 		return nil, time.Time{}, false
 	}
 
 	if item.Expiration > 0 {
 		if time.Now().UnixNano() > item.Expiration {
+			atomic.AddInt64(&c.misses, 1)
 			return nil, time.Time{}, false
 		}
 
+		if c.policy != nil {
+			c.policy.RecordAccess(k)
+		}
+		atomic.AddInt64(&c.hits, 1)
 		return item.Object, time.Unix(0, item.Expiration), true
 	}
 
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	atomic.AddInt64(&c.hits, 1)
 	// 存在且永不超时的 item
 	return item.Object, time.Time{}, true
 }
 
+// GetOrLoad 返回 k 对应的缓存值，如果没有命中就调用 loader 取值并存入 cache
+// （过期时间为 d）。并发场景下，同一个 k 同时发生的多次未命中只会真正执行一次
+// loader：后来的调用者会阻塞等待，拿到和第一个调用者完全相同的结果，避免
+// cache miss 时大量请求同时穿透到后端（惊群）。
+func (c *cache) GetOrLoad(k string, d time.Duration, loader func(key string) (interface{}, error)) (interface{}, error) {
+	return c.GetOrLoadContext(context.Background(), k, d, loader)
+}
+
+// GetOrLoadContext 和 GetOrLoad 一样，但接受一个 context.Context：等待别人加载
+// 结果的调用者可以通过 ctx 取消等待，不会影响正在进行中的那次 loader 调用。
+func (c *cache) GetOrLoadContext(ctx context.Context, k string, d time.Duration, loader func(key string) (interface{}, error)) (interface{}, error) {
+	if c.shards != nil {
+		return c.shardFor(k).GetOrLoadContext(ctx, k, d, loader)
+	}
+	if v, found := c.Get(k); found {
+		return v, nil
+	}
+
+	c.inflightMu.Lock()
+	if c.inflight == nil {
+		c.inflight = make(map[string]*inflightCall)
+	}
+	if call, ok := c.inflight[k]; ok {
+		c.inflightMu.Unlock()
+		return c.waitInflight(ctx, call)
+	}
+	call := new(inflightCall)
+	call.wg.Add(1)
+	c.inflight[k] = call
+	c.inflightMu.Unlock()
+
+	// 用 defer/recover 包住 loader 调用：哪怕 loader panic，也要保证 inflight
+	// 记录被清理、wg.Done 被调用，否则这个 key 上正在等待的以及后续所有调用者
+	// 都会永久阻塞在 waitInflight 里。
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				call.val = nil
+				call.err = fmt.Errorf("cache: loader for key %s panicked: %v", k, r)
+			}
+			c.inflightMu.Lock()
+			delete(c.inflight, k)
+			c.inflightMu.Unlock()
+			call.wg.Done()
+		}()
+		call.val, call.err = loader(k)
+		if call.err == nil {
+			c.Set(k, call.val, d)
+		}
+	}()
+
+	return call.val, call.err
+}
+
+// waitInflight 等待别的 goroutine 正在进行的 loader 调用结束，或者 ctx 被取消
+func (c *cache) waitInflight(ctx context.Context, call *inflightCall) (interface{}, error) {
+	done := make(chan struct{})
+	go func() {
+		call.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return call.val, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func (c *cache) get(k string) (interface{}, bool) {
 	item, found := c.items[k]
 	if !found {
@@ -256,13 +623,333 @@ func (c *cache) get(k string) (interface{}, bool) {
 	return item.Object, true
 }
 
+// Increment 给 key 对应的值加上 n，支持所有内建整型/无符号整型/浮点型，原地修改
+// 并保留原有的过期时间。如果 key 不存在，或者存的不是数字类型，返回 error。
+// 相比 Get -> 类型断言 -> +n -> Set 这一套自己加锁的做法，这里在同一把写锁下
+// 完成读取、运算、写回，不会和 janitor 或其它写操作产生竞争。
+func (c *cache) Increment(k string, n int64) error {
+	if c.shards != nil {
+		return c.shardFor(k).Increment(k, n)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		return fmt.Errorf("Item %s not found", k)
+	}
+	switch v.Object.(type) {
+	case int:
+		v.Object = v.Object.(int) + int(n)
+	case int8:
+		v.Object = v.Object.(int8) + int8(n)
+	case int16:
+		v.Object = v.Object.(int16) + int16(n)
+	case int32:
+		v.Object = v.Object.(int32) + int32(n)
+	case int64:
+		v.Object = v.Object.(int64) + n
+	case uint:
+		v.Object = v.Object.(uint) + uint(n)
+	case uintptr:
+		v.Object = v.Object.(uintptr) + uintptr(n)
+	case uint8:
+		v.Object = v.Object.(uint8) + uint8(n)
+	case uint16:
+		v.Object = v.Object.(uint16) + uint16(n)
+	case uint32:
+		v.Object = v.Object.(uint32) + uint32(n)
+	case uint64:
+		v.Object = v.Object.(uint64) + uint64(n)
+	case float32:
+		v.Object = v.Object.(float32) + float32(n)
+	case float64:
+		v.Object = v.Object.(float64) + float64(n)
+	default:
+		return fmt.Errorf("The value for %s is not an integer", k)
+	}
+	c.items[k] = v
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	return nil
+}
+
+// IncrementFloat 和 Increment 类似，但只接受浮点型的 n，用于给 float32/float64
+// 类型的值做自增
+func (c *cache) IncrementFloat(k string, n float64) error {
+	if c.shards != nil {
+		return c.shardFor(k).IncrementFloat(k, n)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		return fmt.Errorf("Item %s not found", k)
+	}
+	switch v.Object.(type) {
+	case float32:
+		v.Object = v.Object.(float32) + float32(n)
+	case float64:
+		v.Object = v.Object.(float64) + n
+	default:
+		return fmt.Errorf("The value for %s does not have type float32 or float64", k)
+	}
+	c.items[k] = v
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	return nil
+}
+
+// IncrementInt 在 key 存的是 int 时给它加上 n，返回加后的新值
+func (c *cache) IncrementInt(k string, n int) (int, error) {
+	if c.shards != nil {
+		return c.shardFor(k).IncrementInt(k, n)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		return 0, fmt.Errorf("Item %s not found", k)
+	}
+	rv, ok := v.Object.(int)
+	if !ok {
+		return 0, fmt.Errorf("The value for %s is not an int", k)
+	}
+	if (n > 0 && rv > math.MaxInt-n) || (n < 0 && rv < math.MinInt-n) {
+		return 0, fmt.Errorf("Incrementing %s by %d would overflow", k, n)
+	}
+	rv += n
+	v.Object = rv
+	c.items[k] = v
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	return rv, nil
+}
+
+// IncrementInt32 在 key 存的是 int32 时给它加上 n，返回加后的新值
+func (c *cache) IncrementInt32(k string, n int32) (int32, error) {
+	if c.shards != nil {
+		return c.shardFor(k).IncrementInt32(k, n)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		return 0, fmt.Errorf("Item %s not found", k)
+	}
+	rv, ok := v.Object.(int32)
+	if !ok {
+		return 0, fmt.Errorf("The value for %s is not an int32", k)
+	}
+	if (n > 0 && rv > math.MaxInt32-n) || (n < 0 && rv < math.MinInt32-n) {
+		return 0, fmt.Errorf("Incrementing %s by %d would overflow", k, n)
+	}
+	rv += n
+	v.Object = rv
+	c.items[k] = v
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	return rv, nil
+}
+
+// IncrementInt64 在 key 存的是 int64 时给它加上 n，返回加后的新值
+func (c *cache) IncrementInt64(k string, n int64) (int64, error) {
+	if c.shards != nil {
+		return c.shardFor(k).IncrementInt64(k, n)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		return 0, fmt.Errorf("Item %s not found", k)
+	}
+	rv, ok := v.Object.(int64)
+	if !ok {
+		return 0, fmt.Errorf("The value for %s is not an int64", k)
+	}
+	if (n > 0 && rv > math.MaxInt64-n) || (n < 0 && rv < math.MinInt64-n) {
+		return 0, fmt.Errorf("Incrementing %s by %d would overflow", k, n)
+	}
+	rv += n
+	v.Object = rv
+	c.items[k] = v
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	return rv, nil
+}
+
+// IncrementUint 在 key 存的是 uint 时给它加上 n，返回加后的新值
+func (c *cache) IncrementUint(k string, n uint) (uint, error) {
+	if c.shards != nil {
+		return c.shardFor(k).IncrementUint(k, n)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		return 0, fmt.Errorf("Item %s not found", k)
+	}
+	rv, ok := v.Object.(uint)
+	if !ok {
+		return 0, fmt.Errorf("The value for %s is not an uint", k)
+	}
+	if n > ^uint(0)-rv {
+		return 0, fmt.Errorf("Incrementing %s by %d would overflow an unsigned value", k, n)
+	}
+	rv += n
+	v.Object = rv
+	c.items[k] = v
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	return rv, nil
+}
+
+// IncrementFloat32 在 key 存的是 float32 时给它加上 n，返回加后的新值
+func (c *cache) IncrementFloat32(k string, n float32) (float32, error) {
+	if c.shards != nil {
+		return c.shardFor(k).IncrementFloat32(k, n)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		return 0, fmt.Errorf("Item %s not found", k)
+	}
+	rv, ok := v.Object.(float32)
+	if !ok {
+		return 0, fmt.Errorf("The value for %s is not a float32", k)
+	}
+	sum := rv + n
+	if math.IsInf(float64(sum), 0) && !math.IsInf(float64(rv), 0) {
+		return 0, fmt.Errorf("Incrementing %s by %v would overflow a float32 value", k, n)
+	}
+	rv = sum
+	v.Object = rv
+	c.items[k] = v
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	return rv, nil
+}
+
+// IncrementFloat64 在 key 存的是 float64 时给它加上 n，返回加后的新值
+func (c *cache) IncrementFloat64(k string, n float64) (float64, error) {
+	if c.shards != nil {
+		return c.shardFor(k).IncrementFloat64(k, n)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		return 0, fmt.Errorf("Item %s not found", k)
+	}
+	rv, ok := v.Object.(float64)
+	if !ok {
+		return 0, fmt.Errorf("The value for %s is not a float64", k)
+	}
+	sum := rv + n
+	if math.IsInf(sum, 0) && !math.IsInf(rv, 0) {
+		return 0, fmt.Errorf("Incrementing %s by %v would overflow a float64 value", k, n)
+	}
+	rv = sum
+	v.Object = rv
+	c.items[k] = v
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	return rv, nil
+}
+
+// Decrement 和 Increment 相反，给 key 对应的值减去 n
+func (c *cache) Decrement(k string, n int64) error {
+	return c.Increment(k, -n)
+}
+
+// DecrementFloat 和 IncrementFloat 相反，给 key 对应的值减去 n
+func (c *cache) DecrementFloat(k string, n float64) error {
+	return c.IncrementFloat(k, -n)
+}
+
+// DecrementInt 和 IncrementInt 相反
+func (c *cache) DecrementInt(k string, n int) (int, error) {
+	return c.IncrementInt(k, -n)
+}
+
+// DecrementInt32 和 IncrementInt32 相反
+func (c *cache) DecrementInt32(k string, n int32) (int32, error) {
+	return c.IncrementInt32(k, -n)
+}
+
+// DecrementInt64 和 IncrementInt64 相反
+func (c *cache) DecrementInt64(k string, n int64) (int64, error) {
+	return c.IncrementInt64(k, -n)
+}
+
+// DecrementUint 和 IncrementUint 相反，n 为无符号数，溢出（结果为负）时返回 error
+func (c *cache) DecrementUint(k string, n uint) (uint, error) {
+	if c.shards != nil {
+		return c.shardFor(k).DecrementUint(k, n)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		return 0, fmt.Errorf("Item %s not found", k)
+	}
+	rv, ok := v.Object.(uint)
+	if !ok {
+		return 0, fmt.Errorf("The value for %s is not an uint", k)
+	}
+	if n > rv {
+		return 0, fmt.Errorf("Decrementing %s by %d would underflow an unsigned value", k, n)
+	}
+	rv -= n
+	v.Object = rv
+	c.items[k] = v
+	if c.policy != nil {
+		c.policy.RecordAccess(k)
+	}
+	return rv, nil
+}
+
+// DecrementFloat32 和 IncrementFloat32 相反
+func (c *cache) DecrementFloat32(k string, n float32) (float32, error) {
+	return c.IncrementFloat32(k, -n)
+}
+
+// DecrementFloat64 和 IncrementFloat64 相反
+func (c *cache) DecrementFloat64(k string, n float64) (float64, error) {
+	return c.IncrementFloat64(k, -n)
+}
+
 // 从 cache 中删除 item，如果 cache 中没有，什么也不做
 func (c *cache) Delete(k string) {
+	if c.shards != nil {
+		c.shardFor(k).Delete(k)
+		return
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	// 逐出
 	v, evicted := c.delete(k)
+	if c.policy != nil {
+		c.policy.Remove(k)
+	}
 	if evicted {
 		c.onEvicted(k, v)
 	}
@@ -286,14 +973,26 @@ type keyAndValue struct {
 
 // 删除超时的 item，并执行指定的钩子函数（可选）
 func (c *cache) DeleteExpired() {
+	if c.shards != nil {
+		for _, s := range c.shards {
+			s.DeleteExpired()
+		}
+		return
+	}
 	var evictedItems []keyAndValue
 	now := time.Now().UnixNano()
+	start := time.Now()
+	removed := 0
 	c.mu.Lock()
 
 	// 将过期的item删除的同时，保留一份给删除钩子来执行
 	for k, v := range c.items {
 		if v.Expiration > 0 && now > v.Expiration {
 			ov, evicted := c.delete(k)
+			if c.policy != nil {
+				c.policy.Remove(k)
+			}
+			removed++
 			if evicted {
 				evictedItems = append(evictedItems, keyAndValue{k, ov})
 			}
@@ -301,6 +1000,13 @@ func (c *cache) DeleteExpired() {
 	}
 	c.mu.Unlock()
 
+	duration := time.Since(start)
+	atomic.AddInt64(&c.sweeps, 1)
+	atomic.AddInt64(&c.sweepNanos, int64(duration))
+	atomic.AddInt64(&c.sweepItemsRemoved, int64(removed))
+	atomic.AddInt64(&c.evictions, int64(removed))
+	recordSweepDuration(&c.sweepHistogram, duration)
+
 	// 删除钩子方法对已删除的 item 做处理
 	for _, v := range evictedItems {
 		c.onEvicted(v.key, v.value)
@@ -309,12 +1015,25 @@ func (c *cache) DeleteExpired() {
 
 // 为 cache 对象 添加一个删除时的钩子方法
 func (c *cache) OnEvicted(f func(string, interface{})) {
+	if c.shards != nil {
+		for _, s := range c.shards {
+			s.OnEvicted(f)
+		}
+		return
+	}
 	c.mu.Lock()
 	c.onEvicted = f
 	c.mu.Unlock()
 }
 
 func (c *cache) ItemCount() int {
+	if c.shards != nil {
+		n := 0
+		for _, s := range c.shards {
+			n += s.ItemCount()
+		}
+		return n
+	}
 	c.mu.RLock()
 	n := len(c.items)
 	c.mu.RUnlock()
@@ -322,11 +1041,114 @@ func (c *cache) ItemCount() int {
 }
 
 func (c *cache) Flush() {
+	if c.shards != nil {
+		for _, s := range c.shards {
+			s.Flush()
+		}
+		return
+	}
 	c.mu.Lock()
+	if c.policy != nil {
+		for k := range c.items {
+			c.policy.Remove(k)
+		}
+	}
 	c.items = map[string]Item{}
 	c.mu.Unlock()
 }
 
+// Save 把当前 cache 中所有未过期的 item 用 encoding/gob 编码后写入 w，方便在进程
+// 重启前做持久化，配合 Load/LoadFile 和 NewFrom 实现重启后的快速恢复。
+// Save 会自动向 gob 注册当前 item 里存放的具体类型；在没有调用过 Save 的进程里
+// Load 自定义类型之前，须先用 Register 向 gob 注册该类型。
+func (c *cache) Save(w io.Writer) (err error) {
+	enc := gob.NewEncoder(w)
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("Error registering item types with Gob library")
+		}
+	}()
+
+	items := c.Items()
+	for _, v := range items {
+		gob.Register(v.Object)
+	}
+	err = enc.Encode(&items)
+	return
+}
+
+// SaveFile 是 Save 的便捷封装，直接把 cache 写入指定路径的文件
+func (c *cache) SaveFile(fname string) error {
+	fp, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	err = c.Save(fp)
+	if err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+// Load 从 r 中读取用 gob 编码的 item，合并（而不是替换）进当前 cache：
+// 对于已经存在且未过期的 key 会被跳过，只有缺失或已过期的 key 才会被覆盖，
+// 这样才能安全地在 NewFrom/New 返回的 cache 上反复调用。
+func (c *cache) Load(r io.Reader) error {
+	dec := gob.NewDecoder(r)
+	items := map[string]Item{}
+	err := dec.Decode(&items)
+	if err != nil {
+		return err
+	}
+	if c.shards != nil {
+		for k, v := range items {
+			c.shardFor(k).loadOne(k, v)
+		}
+		return nil
+	}
+	for k, v := range items {
+		c.loadOne(k, v)
+	}
+	return nil
+}
+
+// loadOne 合并 Load 里解码出来的单个 item：已经存在且未过期的 key 会被跳过，
+// 否则像 Set 一样写入并走 afterInsert，这样淘汰策略和 Stats 计数器才知道这个
+// key 的存在——不然在 NewWithPolicy 的有界 cache 上，Load 进来的 key 既不会被
+// 计入 MaxItems，也永远不会被策略淘汰。
+func (c *cache) loadOne(k string, v Item) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ov, found := c.items[k]
+	if found && !ov.Expired() {
+		return
+	}
+	c.items[k] = v
+	c.afterInsert(k, found)
+}
+
+// LoadFile 是 Load 的便捷封装，直接从指定路径的文件读取
+func (c *cache) LoadFile(fname string) error {
+	fp, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	err = c.Load(fp)
+	if err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+// Register 包装 gob.Register，用于提前注册存放在 Item.Object（interface{}）里的
+// 具体类型，否则 Save/Load 在编解码这些类型时会失败
+func Register(value interface{}) {
+	gob.Register(value)
+}
+
 type janitor struct {
 	Interval time.Duration
 	stop     chan bool
@@ -348,6 +1170,14 @@ func (j *janitor) Run(c *cache) {
 
 // 停止 janitor 的『等待超时然后删除』的操作
 func stopJanitor(c *Cache) {
+	if c.shards != nil {
+		for _, s := range c.shards {
+			if s.janitor != nil {
+				s.janitor.stop <- true
+			}
+		}
+		return
+	}
 	c.janitor.stop <- true
 }
 
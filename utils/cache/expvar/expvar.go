@@ -0,0 +1,22 @@
+// Package expvar 提供一个把 cache.Cache 的统计信息发布到标准库 expvar 的小工具，
+// 不需要额外的监控依赖，在 /debug/vars 里就能直接看到命中率等指标。
+//
+// 因为包名和标准库 "expvar" 重名，引入时通常需要加一个别名：
+//
+//	cacheexpvar "github.com/tmpbook/The-Useless-Go/utils/cache/expvar"
+package expvar
+
+import (
+	stdexpvar "expvar"
+
+	"github.com/tmpbook/The-Useless-Go/utils/cache"
+)
+
+// Publish 把 c.Stats() 用 name 注册到 expvar，每次被访问时都会取一份最新的快照。
+// 和 expvar.Publish 的行为一致，用重复的 name 调用会 panic，调用方自己保证
+// name 在进程内唯一。
+func Publish(name string, c *cache.Cache) {
+	stdexpvar.Publish(name, stdexpvar.Func(func() interface{} {
+		return c.Stats()
+	}))
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadDedupesConcurrentMisses(t *testing.T) {
+	c := New(NoExpiration, 0)
+	var calls int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad("k", NoExpiration, func(string) (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(20 * time.Millisecond)
+				return 42, nil
+			})
+			if err != nil || v != 42 {
+				t.Errorf("got %v, %v; want 42, nil", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("loader called %d times, want 1", calls)
+	}
+}
+
+func TestGetOrLoadContextCancelDoesNotLeakInflight(t *testing.T) {
+	c := New(NoExpiration, 0)
+	release := make(chan struct{})
+
+	go func() {
+		c.GetOrLoad("k", NoExpiration, func(string) (interface{}, error) {
+			<-release
+			return 1, nil
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetOrLoadContext(ctx, "k", NoExpiration, func(string) (interface{}, error) {
+		t.Fatal("loader should not run for a waiter; the first call already owns the inflight entry")
+		return nil, nil
+	}); err == nil {
+		t.Fatal("expected the cancelled waiter to return an error")
+	}
+	close(release)
+}
+
+func TestGetOrLoadRecoversFromPanickingLoader(t *testing.T) {
+	c := New(NoExpiration, 0)
+
+	func() {
+		defer func() { recover() }()
+		c.GetOrLoad("k", NoExpiration, func(string) (interface{}, error) {
+			panic("boom")
+		})
+	}()
+
+	// A panicking loader must not wedge the inflight entry: a later call for
+	// the same key has to run its own loader instead of hanging forever.
+	done := make(chan struct{})
+	go func() {
+		v, err := c.GetOrLoad("k", NoExpiration, func(string) (interface{}, error) {
+			return 7, nil
+		})
+		if err != nil || v != 7 {
+			t.Errorf("got %v, %v; want 7, nil", v, err)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("GetOrLoad for the same key hung after a panicking loader")
+	}
+}
@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIncrementDecrementBasic(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Set("i", 10, NoExpiration)
+	if err := c.Increment("i", 5); err != nil {
+		t.Fatalf("Increment: %v", err)
+	}
+	if v, _ := c.Get("i"); v != 15 {
+		t.Fatalf("got %v, want 15", v)
+	}
+	if err := c.Decrement("i", 3); err != nil {
+		t.Fatalf("Decrement: %v", err)
+	}
+	if v, _ := c.Get("i"); v != 12 {
+		t.Fatalf("got %v, want 12", v)
+	}
+}
+
+func TestIncrementMissingOrWrongType(t *testing.T) {
+	c := New(NoExpiration, 0)
+	if err := c.Increment("missing", 1); err == nil {
+		t.Fatalf("expected error incrementing a missing key")
+	}
+	c.Set("s", "not a number", NoExpiration)
+	if err := c.Increment("s", 1); err == nil {
+		t.Fatalf("expected error incrementing a non-numeric value")
+	}
+}
+
+func TestIncrementIntOverflow(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Set("i", math.MaxInt-1, NoExpiration)
+	if _, err := c.IncrementInt("i", 10); err == nil {
+		t.Fatalf("expected overflow error incrementing near math.MaxInt")
+	}
+	if v, _ := c.Get("i"); v != math.MaxInt-1 {
+		t.Fatalf("value changed despite rejected overflow: got %v", v)
+	}
+}
+
+func TestIncrementInt32Overflow(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Set("i", int32(math.MaxInt32-1), NoExpiration)
+	if _, err := c.IncrementInt32("i", 10); err == nil {
+		t.Fatalf("expected overflow error incrementing near math.MaxInt32")
+	}
+}
+
+func TestIncrementInt64Overflow(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Set("i", int64(math.MaxInt64-1), NoExpiration)
+	if _, err := c.IncrementInt64("i", 10); err == nil {
+		t.Fatalf("expected overflow error incrementing near math.MaxInt64")
+	}
+}
+
+func TestIncrementUintOverflow(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Set("i", ^uint(0), NoExpiration)
+	if _, err := c.IncrementUint("i", 1); err == nil {
+		t.Fatalf("expected overflow error incrementing a maxed-out uint")
+	}
+}
+
+func TestIncrementFloat32Overflow(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Set("i", float32(math.MaxFloat32), NoExpiration)
+	if _, err := c.IncrementFloat32("i", float32(math.MaxFloat32)); err == nil {
+		t.Fatalf("expected overflow error incrementing a float32 past its max")
+	}
+}
+
+func TestIncrementFloat64Overflow(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Set("i", math.MaxFloat64, NoExpiration)
+	if _, err := c.IncrementFloat64("i", math.MaxFloat64); err == nil {
+		t.Fatalf("expected overflow error incrementing a float64 past its max")
+	}
+}
+
+func TestDecrementIntUnderflow(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Set("i", math.MinInt+1, NoExpiration)
+	if _, err := c.DecrementInt("i", 10); err == nil {
+		t.Fatalf("expected underflow error decrementing near math.MinInt")
+	}
+}
+
+func TestDecrementUintUnderflow(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Set("i", uint(1), NoExpiration)
+	if _, err := c.DecrementUint("i", 2); err == nil {
+		t.Fatalf("expected underflow error decrementing an unsigned value below zero")
+	}
+}
+
+// TestIncrementFamilyRecordsAccess 确认 Increment/Decrement 的每一个类型化变体都
+// 像 Get/Set 一样把访问上报给淘汰策略，否则一个被高频自增的 key 会被 LRU/LFU
+// 当成冷 key 提前淘汰。每个 case 先插入 hot，再插入 other 让 hot 变成最久未被
+// 触碰的 key，然后调用待测的 increment/decrement 操作，最后插入第三个 key 触发
+// 淘汰：如果操作正确上报了访问，被淘汰的应该是 other 而不是 hot。
+func TestIncrementFamilyRecordsAccess(t *testing.T) {
+	cases := []struct {
+		name      string
+		init      interface{}
+		increment func(c *Cache)
+	}{
+		{"Increment", int64(0), func(c *Cache) { c.Increment("hot", 1) }},
+		{"IncrementFloat", float64(0), func(c *Cache) { c.IncrementFloat("hot", 1) }},
+		{"IncrementInt", 0, func(c *Cache) { c.IncrementInt("hot", 1) }},
+		{"IncrementInt32", int32(0), func(c *Cache) { c.IncrementInt32("hot", 1) }},
+		{"IncrementInt64", int64(0), func(c *Cache) { c.IncrementInt64("hot", 1) }},
+		{"IncrementUint", uint(0), func(c *Cache) { c.IncrementUint("hot", 1) }},
+		{"IncrementFloat32", float32(0), func(c *Cache) { c.IncrementFloat32("hot", 1) }},
+		{"IncrementFloat64", float64(0), func(c *Cache) { c.IncrementFloat64("hot", 1) }},
+		{"Decrement", int64(10), func(c *Cache) { c.Decrement("hot", 1) }},
+		{"DecrementFloat", float64(10), func(c *Cache) { c.DecrementFloat("hot", 1) }},
+		{"DecrementInt", 10, func(c *Cache) { c.DecrementInt("hot", 1) }},
+		{"DecrementInt32", int32(10), func(c *Cache) { c.DecrementInt32("hot", 1) }},
+		{"DecrementInt64", int64(10), func(c *Cache) { c.DecrementInt64("hot", 1) }},
+		{"DecrementUint", uint(10), func(c *Cache) { c.DecrementUint("hot", 1) }},
+		{"DecrementFloat32", float32(10), func(c *Cache) { c.DecrementFloat32("hot", 1) }},
+		{"DecrementFloat64", float64(10), func(c *Cache) { c.DecrementFloat64("hot", 1) }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewWithPolicy(NoExpiration, 0, 2, NewLRU())
+			c.Set("hot", tc.init, NoExpiration)
+			c.Set("other", 0, NoExpiration)
+			tc.increment(c)
+			c.Set("evictor", 0, NoExpiration)
+
+			if _, found := c.Get("hot"); !found {
+				t.Fatalf("%s did not RecordAccess; hot key was evicted ahead of untouched \"other\"", tc.name)
+			}
+			if _, found := c.Get("other"); found {
+				t.Fatalf("%s: expected untouched \"other\" to be evicted instead of hot", tc.name)
+			}
+		})
+	}
+}
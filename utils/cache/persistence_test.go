@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	src := New(NoExpiration, 0)
+	src.Set("a", 1, NoExpiration)
+	src.Set("b", "two", NoExpiration)
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	dst := New(NoExpiration, 0)
+	if err := dst.Load(&buf); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if v, found := dst.Get("a"); !found || v != 1 {
+		t.Fatalf("dst[\"a\"] = %v, %v; want 1, true", v, found)
+	}
+	if v, found := dst.Get("b"); !found || v != "two" {
+		t.Fatalf("dst[\"b\"] = %v, %v; want two, true", v, found)
+	}
+}
+
+func TestLoadSkipsExistingUnexpiredKeys(t *testing.T) {
+	src := New(NoExpiration, 0)
+	src.Set("a", "from src", NoExpiration)
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	dst := New(NoExpiration, 0)
+	dst.Set("a", "already here", NoExpiration)
+	if err := dst.Load(&buf); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if v, _ := dst.Get("a"); v != "already here" {
+		t.Fatalf("Load overwrote an existing unexpired key: got %v, want %q", v, "already here")
+	}
+}
+
+func TestLoadOverwritesExpiredKeys(t *testing.T) {
+	src := New(NoExpiration, 0)
+	src.Set("a", "fresh", NoExpiration)
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	dst := New(NoExpiration, 0)
+	dst.Set("a", "stale", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	if err := dst.Load(&buf); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if v, found := dst.Get("a"); !found || v != "fresh" {
+		t.Fatalf("Load did not overwrite an expired key: got %v, %v; want fresh, true", v, found)
+	}
+}
+
+func TestLoadRespectsMaxItemsAndPolicy(t *testing.T) {
+	src := New(NoExpiration, 0)
+	src.Set("c", 3, NoExpiration)
+	src.Set("d", 4, NoExpiration)
+	src.Set("e", 5, NoExpiration)
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	dst := NewWithPolicy(NoExpiration, 0, 2, NewLRU())
+	dst.Set("a", 1, NoExpiration)
+	dst.Set("b", 2, NoExpiration)
+
+	if err := dst.Load(&buf); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if n := dst.ItemCount(); n != 2 {
+		t.Fatalf("ItemCount() after Load past MaxItems = %d, want 2", n)
+	}
+	if stats := dst.Stats(); stats.Insertions != 5 {
+		t.Fatalf("Insertions = %d, want 5 (2 initial Sets + 3 loaded keys)", stats.Insertions)
+	}
+}
+
+func TestSaveFileLoadFileRoundTrip(t *testing.T) {
+	src := New(NoExpiration, 0)
+	src.Set("a", 1, NoExpiration)
+
+	fname := filepath.Join(t.TempDir(), "cache.gob")
+	if err := src.SaveFile(fname); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+
+	dst := New(NoExpiration, 0)
+	if err := dst.LoadFile(fname); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if v, found := dst.Get("a"); !found || v != 1 {
+		t.Fatalf("dst[\"a\"] = %v, %v; want 1, true", v, found)
+	}
+}
+
+type persistedStruct struct {
+	Name string
+}
+
+func TestRegisteredCustomTypeRoundTrips(t *testing.T) {
+	Register(persistedStruct{})
+
+	src := New(NoExpiration, 0)
+	src.Set("s", persistedStruct{Name: "x"}, NoExpiration)
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	dst := New(NoExpiration, 0)
+	if err := dst.Load(&buf); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	v, found := dst.Get("s")
+	if !found {
+		t.Fatalf("custom-typed item not found after round trip")
+	}
+	ps, ok := v.(persistedStruct)
+	if !ok || ps.Name != "x" {
+		t.Fatalf("dst[\"s\"] = %#v; want persistedStruct{Name: \"x\"}", v)
+	}
+}
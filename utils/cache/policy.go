@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"container/heap"
+	"container/list"
+	"sync"
+)
+
+// EvictionPolicy 决定当 cache 通过 NewWithPolicy 设置了 MaxItems、并且已经满员时，
+// 应该淘汰哪一个 key。内置了 LRU、LFU、TinyLFU 三种实现，也可以自己实现这个接口
+// 接入别的淘汰算法。所有实现都必须是并发安全的，因为是在持有 cache 写锁的情况下
+// 被调用的（不需要自己加这把锁，但内部状态可能被多个 goroutine 通过不同的 cache
+// 实例共享，所以仍然自带了锁）。
+type EvictionPolicy interface {
+	// RecordAccess 在一次成功的 Get/GetWithExpiration 命中、覆盖写入一个已存在的
+	// key（Set/Replace/Load），以及 Increment/Decrement 系列操作成功后被调用
+	RecordAccess(key string)
+	// RecordInsert 在一个全新的 key 被写入 cache 后被调用
+	RecordInsert(key string)
+	// Evict 选出应该被淘汰的 key；策略里已经没有可淘汰的 key 时 ok 返回 false
+	Evict() (key string, ok bool)
+	// Remove 把 key 从策略的内部状态中移除，在显式 Delete、过期淘汰、以及策略
+	// 自身淘汰之后都会被调用，保证策略状态和 cache 里实际的 key 集合一致
+	Remove(key string)
+}
+
+// LRU 基于 container/list 实现最近最少使用（Least Recently Used）淘汰策略：
+// 每次访问或写入都把 key 移到链表头部，Evict 总是淘汰链表尾部的 key。
+type LRU struct {
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// NewLRU 返回一个空的 LRU 策略
+func NewLRU() *LRU {
+	return &LRU{
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// RecordAccess 实现 EvictionPolicy
+func (p *LRU) RecordAccess(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.items[key]; ok {
+		p.ll.MoveToFront(e)
+	}
+}
+
+// RecordInsert 实现 EvictionPolicy
+func (p *LRU) RecordInsert(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.items[key]; ok {
+		p.ll.MoveToFront(e)
+		return
+	}
+	p.items[key] = p.ll.PushFront(key)
+}
+
+// Evict 实现 EvictionPolicy，淘汰最久未被访问的 key
+func (p *LRU) Evict() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e := p.ll.Back()
+	if e == nil {
+		return "", false
+	}
+	key := e.Value.(string)
+	p.ll.Remove(e)
+	delete(p.items, key)
+	return key, true
+}
+
+// Remove 实现 EvictionPolicy
+func (p *LRU) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.items[key]; ok {
+		p.ll.Remove(e)
+		delete(p.items, key)
+	}
+}
+
+// lfuEntry 是 LFU 最小堆里的一个节点
+type lfuEntry struct {
+	key   string
+	freq  int
+	index int
+}
+
+// lfuHeap 是按 freq 排序的最小堆，实现 container/heap.Interface
+type lfuHeap []*lfuEntry
+
+func (h lfuHeap) Len() int            { return len(h) }
+func (h lfuHeap) Less(i, j int) bool  { return h[i].freq < h[j].freq }
+func (h lfuHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i]; h[i].index = i; h[j].index = j }
+func (h *lfuHeap) Push(x interface{}) {
+	e := x.(*lfuEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+func (h *lfuHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*h = old[:n-1]
+	return e
+}
+
+// LFU 基于最小堆实现最不经常使用（Least Frequently Used）淘汰策略：每次访问或
+// 写入都给 key 的访问计数加一，Evict 总是淘汰当前访问计数最小的 key。
+type LFU struct {
+	mu      sync.Mutex
+	h       lfuHeap
+	entries map[string]*lfuEntry
+}
+
+// NewLFU 返回一个空的 LFU 策略
+func NewLFU() *LFU {
+	return &LFU{entries: make(map[string]*lfuEntry)}
+}
+
+// RecordAccess 实现 EvictionPolicy
+func (p *LFU) RecordAccess(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.entries[key]; ok {
+		e.freq++
+		heap.Fix(&p.h, e.index)
+	}
+}
+
+// RecordInsert 实现 EvictionPolicy
+func (p *LFU) RecordInsert(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.entries[key]; ok {
+		e.freq++
+		heap.Fix(&p.h, e.index)
+		return
+	}
+	e := &lfuEntry{key: key, freq: 1}
+	p.entries[key] = e
+	heap.Push(&p.h, e)
+}
+
+// Evict 实现 EvictionPolicy，淘汰访问计数最小的 key
+func (p *LFU) Evict() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.h.Len() == 0 {
+		return "", false
+	}
+	e := heap.Pop(&p.h).(*lfuEntry)
+	delete(p.entries, e.key)
+	return e.key, true
+}
+
+// Remove 实现 EvictionPolicy
+func (p *LFU) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.entries[key]; ok {
+		heap.Remove(&p.h, e.index)
+		delete(p.entries, key)
+	}
+}
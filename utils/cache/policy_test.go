@@ -0,0 +1,82 @@
+package cache
+
+import "testing"
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewWithPolicy(NoExpiration, 0, 2, NewLRU())
+	c.Set("hot", 1, NoExpiration)
+	c.Set("cold", 2, NoExpiration)
+
+	for i := 0; i < 5; i++ {
+		if _, found := c.Get("hot"); !found {
+			t.Fatalf("hot should still be present before the evicting insert")
+		}
+	}
+
+	c.Set("new", 3, NoExpiration)
+
+	if _, found := c.Get("hot"); !found {
+		t.Fatalf("hot was evicted even though it was accessed repeatedly")
+	}
+	if _, found := c.Get("cold"); found {
+		t.Fatalf("cold should have been evicted instead of hot")
+	}
+}
+
+func TestLFUEvictsLeastFrequentlyUsed(t *testing.T) {
+	c := NewWithPolicy(NoExpiration, 0, 2, NewLFU())
+	c.Set("hot", 1, NoExpiration)
+	c.Set("cold", 2, NoExpiration)
+
+	for i := 0; i < 5; i++ {
+		c.Get("hot")
+	}
+
+	c.Set("new", 3, NoExpiration)
+
+	if _, found := c.Get("hot"); !found {
+		t.Fatalf("hot was evicted even though it was accessed far more often than cold")
+	}
+	if _, found := c.Get("cold"); found {
+		t.Fatalf("cold should have been evicted instead of hot")
+	}
+}
+
+// TestTinyLFURescuesAccessedZombieFromPending 复现了一个具体的 bug：当
+// NewTinyLFU 的 capacity 比 cache 真正的 MaxItems 小（文档明确允许这种用法）时，
+// 一个已经被 admit 判定淘汰、还躺在 pending 队列里等 Evict() 取走的 key，即使之后
+// 被高频访问，RecordAccess 也完全不认识它，所以它永远没有机会被救回来。
+func TestTinyLFURescuesAccessedZombieFromPending(t *testing.T) {
+	policy := NewTinyLFU(10)
+	c := NewWithPolicy(NoExpiration, 0, 50, policy)
+
+	for i := 0; i < 50; i++ {
+		c.Set(string(rune('a'+i%26))+string(rune('A'+i/26)), i, NoExpiration)
+	}
+
+	zombie, ok := policy.Evict()
+	if !ok {
+		t.Fatalf("expected at least one pending candidate after filling the cache past TinyLFU's window")
+	}
+	// zombie 本来就还在 c.items 里（Evict() 只是从 policy 里取走了淘汰候选，并不
+	// 会真的调用 c.delete），这里把它放回 pending，模拟它在真正被淘汰前又被访问
+	// 到的情况。
+	policy.pending = append(policy.pending, zombie)
+
+	for i := 0; i < 100000; i++ {
+		if _, found := c.Get(zombie); !found {
+			t.Fatalf("zombie key %q disappeared from the cache mid-test", zombie)
+		}
+	}
+
+	for _, k := range policy.pending {
+		if k == zombie {
+			t.Fatalf("hot zombie key %q is still condemned in pending; RecordAccess must rescue it", zombie)
+		}
+	}
+	if _, inProbation := policy.probationM[zombie]; !inProbation {
+		if _, inProtected := policy.protectedM[zombie]; !inProtected {
+			t.Fatalf("rescued zombie key %q was not re-admitted into probation or protected", zombie)
+		}
+	}
+}
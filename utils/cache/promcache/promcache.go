@@ -0,0 +1,74 @@
+// Package promcache 把 cache.Cache 的统计信息包装成一个 prometheus.Collector，
+// 方便注册到 Prometheus 的 Registry 里统一采集。
+package promcache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/tmpbook/The-Useless-Go/utils/cache"
+)
+
+// collector 实现 prometheus.Collector：每次被 Gather 时从 c.Stats() 取一份
+// 最新的快照，转换成标准的 counter/gauge。
+type collector struct {
+	c *cache.Cache
+
+	itemCount         *prometheus.Desc
+	hits              *prometheus.Desc
+	misses            *prometheus.Desc
+	evictions         *prometheus.Desc
+	insertions        *prometheus.Desc
+	sweeps            *prometheus.Desc
+	sweepItemsRemoved *prometheus.Desc
+	sweepDuration     *prometheus.Desc
+}
+
+// NewCollector 返回一个包装了 c 的 prometheus.Collector，name 会作为所有指标名
+// 的前缀，用于区分同一进程里注册的多个 cache 实例。
+func NewCollector(name string, c *cache.Cache) prometheus.Collector {
+	return &collector{
+		c:                 c,
+		itemCount:         prometheus.NewDesc(name+"_cache_items", "Number of items currently in the cache.", nil, nil),
+		hits:              prometheus.NewDesc(name+"_cache_hits_total", "Number of cache hits.", nil, nil),
+		misses:            prometheus.NewDesc(name+"_cache_misses_total", "Number of cache misses.", nil, nil),
+		evictions:         prometheus.NewDesc(name+"_cache_evictions_total", "Number of items evicted due to expiration or the eviction policy.", nil, nil),
+		insertions:        prometheus.NewDesc(name+"_cache_insertions_total", "Number of new keys inserted into the cache.", nil, nil),
+		sweeps:            prometheus.NewDesc(name+"_cache_sweeps_total", "Number of janitor sweeps run.", nil, nil),
+		sweepItemsRemoved: prometheus.NewDesc(name+"_cache_sweep_items_removed_total", "Number of items removed by janitor sweeps.", nil, nil),
+		sweepDuration:     prometheus.NewDesc(name+"_cache_sweep_duration_seconds", "Distribution of per-sweep (DeleteExpired) duration, to help tune cleanupInterval.", nil, nil),
+	}
+}
+
+// Describe 实现 prometheus.Collector
+func (col *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- col.itemCount
+	ch <- col.hits
+	ch <- col.misses
+	ch <- col.evictions
+	ch <- col.insertions
+	ch <- col.sweeps
+	ch <- col.sweepItemsRemoved
+	ch <- col.sweepDuration
+}
+
+// Collect 实现 prometheus.Collector
+func (col *collector) Collect(ch chan<- prometheus.Metric) {
+	stats := col.c.Stats()
+	ch <- prometheus.MustNewConstMetric(col.itemCount, prometheus.GaugeValue, float64(col.c.ItemCount()))
+	ch <- prometheus.MustNewConstMetric(col.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(col.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(col.evictions, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(col.insertions, prometheus.CounterValue, float64(stats.Insertions))
+	ch <- prometheus.MustNewConstMetric(col.sweeps, prometheus.CounterValue, float64(stats.Sweeps))
+	ch <- prometheus.MustNewConstMetric(col.sweepItemsRemoved, prometheus.CounterValue, float64(stats.SweepItemsRemoved))
+
+	hist := stats.SweepDurationHistogram
+	buckets := make(map[float64]uint64, len(hist.Bounds))
+	var cumulative uint64
+	for i, bound := range hist.Bounds {
+		cumulative += uint64(hist.Counts[i])
+		buckets[bound.Seconds()] = cumulative
+	}
+	cumulative += uint64(hist.Counts[len(hist.Counts)-1])
+	ch <- prometheus.MustNewConstHistogram(col.sweepDuration, cumulative, stats.SweepDuration.Seconds(), buckets)
+}
@@ -0,0 +1,29 @@
+package promcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/tmpbook/The-Useless-Go/utils/cache"
+)
+
+func TestCollectorExposesSweepMetrics(t *testing.T) {
+	c := cache.New(time.Millisecond, 0)
+	c.Set("a", 1, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	c.DeleteExpired()
+
+	col := NewCollector("test", c)
+
+	if n := testutil.CollectAndCount(col, "test_cache_sweeps_total"); n != 1 {
+		t.Fatalf("test_cache_sweeps_total series = %d, want 1", n)
+	}
+	if n := testutil.CollectAndCount(col, "test_cache_sweep_items_removed_total"); n != 1 {
+		t.Fatalf("test_cache_sweep_items_removed_total series = %d, want 1", n)
+	}
+	if n := testutil.CollectAndCount(col, "test_cache_sweep_duration_seconds"); n != 1 {
+		t.Fatalf("test_cache_sweep_duration_seconds series = %d, want 1", n)
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestShardedRoutingIsConsistent(t *testing.T) {
+	c := NewSharded(NoExpiration, 0, 8)
+	root := c.cache
+
+	for _, k := range []string{"a", "b", "c", "foo", "bar", "baz", "quux"} {
+		c.Set(k, k, NoExpiration)
+		first := root.shardFor(k)
+		for i := 0; i < 5; i++ {
+			if second := root.shardFor(k); second != first {
+				t.Fatalf("shardFor(%q) is not stable across calls", k)
+			}
+		}
+		v, found := first.Get(k)
+		if !found || v != k {
+			t.Fatalf("key %q not found on its own shard after Set", k)
+		}
+	}
+}
+
+func TestShardedAggregatesAcrossShards(t *testing.T) {
+	c := NewSharded(NoExpiration, 0, 4)
+
+	keys := []string{"k0", "k1", "k2", "k3", "k4", "k5", "k6", "k7", "k8", "k9"}
+	for _, k := range keys {
+		c.Set(k, k, NoExpiration)
+	}
+
+	if n := c.ItemCount(); n != len(keys) {
+		t.Fatalf("ItemCount() = %d, want %d", n, len(keys))
+	}
+
+	items := c.Items()
+	if len(items) != len(keys) {
+		t.Fatalf("Items() returned %d entries, want %d", len(items), len(keys))
+	}
+	for _, k := range keys {
+		if items[k].Object != k {
+			t.Fatalf("Items()[%q] = %v, want %q", k, items[k].Object, k)
+		}
+	}
+
+	var evicted []string
+	c.OnEvicted(func(k string, v interface{}) {
+		evicted = append(evicted, k)
+	})
+	c.Delete(keys[0])
+	if len(evicted) != 1 || evicted[0] != keys[0] {
+		t.Fatalf("OnEvicted hook not triggered across shards for %q, got %v", keys[0], evicted)
+	}
+	if n := c.ItemCount(); n != len(keys)-1 {
+		t.Fatalf("ItemCount() after Delete = %d, want %d", n, len(keys)-1)
+	}
+
+	c.Flush()
+	if n := c.ItemCount(); n != 0 {
+		t.Fatalf("ItemCount() after Flush = %d, want 0", n)
+	}
+}
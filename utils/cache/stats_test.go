@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordSweepDurationBucketsByUpperBound(t *testing.T) {
+	var hist [len(sweepBucketBounds) + 1]int64
+
+	recordSweepDuration(&hist, 0)
+	recordSweepDuration(&hist, time.Millisecond)
+	recordSweepDuration(&hist, 2*time.Millisecond)
+	recordSweepDuration(&hist, time.Second)
+
+	if hist[0] != 2 {
+		t.Fatalf("bucket 0 (<=%s) = %d, want 2", sweepBucketBounds[0], hist[0])
+	}
+	if hist[1] != 1 {
+		t.Fatalf("bucket 1 (<=%s) = %d, want 1", sweepBucketBounds[1], hist[1])
+	}
+	last := len(hist) - 1
+	if hist[last] != 1 {
+		t.Fatalf("overflow bucket = %d, want 1", hist[last])
+	}
+}
+
+func TestStatsSweepHistogramAggregatesAcrossShards(t *testing.T) {
+	c := NewSharded(time.Millisecond, 0, 4)
+	for i := 0; i < 20; i++ {
+		c.Set(string(rune('a'+i)), i, time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	c.DeleteExpired()
+
+	stats := c.Stats()
+	if stats.Sweeps == 0 {
+		t.Fatalf("expected at least one sweep across the shards")
+	}
+	var total int64
+	for _, n := range stats.SweepDurationHistogram.Counts {
+		total += n
+	}
+	if total != stats.Sweeps {
+		t.Fatalf("sweep histogram total = %d, want %d (Stats.Sweeps)", total, stats.Sweeps)
+	}
+	if stats.SweepItemsRemoved == 0 {
+		t.Fatalf("expected SweepItemsRemoved > 0 after sweeping expired keys")
+	}
+}
@@ -0,0 +1,293 @@
+package cache
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+)
+
+// countMinSketch 是一个极简的 4 行 Count-Min Sketch，用来在不存储完整访问历史的
+// 情况下估计一个 key 被访问过多少次。计数器用 uint8 存放并定期减半（老化），这样
+// 近期的热点 key 才会比很久以前被访问过一次的 key 估计频率更高。
+type countMinSketch struct {
+	rows [4][]uint8
+	mask uint32
+}
+
+var cmSeeds = [4]uint32{0x9e3779b9, 0x85ebca6b, 0xc2b2ae35, 0x27d4eb2f}
+
+func newCountMinSketch(width int) *countMinSketch {
+	w := nextPowerOfTwo(width)
+	s := &countMinSketch{mask: w - 1}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, w)
+	}
+	return s
+}
+
+func (s *countMinSketch) indexes(key string) [4]uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	base := h.Sum32()
+	var idx [4]uint32
+	for i, seed := range cmSeeds {
+		idx[i] = (base ^ seed) & s.mask
+	}
+	return idx
+}
+
+func (s *countMinSketch) add(key string) {
+	for i, j := range s.indexes(key) {
+		if s.rows[i][j] < 255 {
+			s.rows[i][j]++
+		}
+	}
+}
+
+func (s *countMinSketch) estimate(key string) uint8 {
+	idx := s.indexes(key)
+	min := s.rows[0][idx[0]]
+	for i := 1; i < len(idx); i++ {
+		if s.rows[i][idx[i]] < min {
+			min = s.rows[i][idx[i]]
+		}
+	}
+	return min
+}
+
+func (s *countMinSketch) age() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] /= 2
+		}
+	}
+}
+
+// TinyLFU 实现 W-TinyLFU 淘汰策略：一个小的 LRU 准入窗口（window），一个 Count-Min
+// Sketch 频率估计器，以及一个分成 probation / protected 两段的 SLRU 主缓存（main）。
+// 窗口满了以后，被挤出来的 key 只有在估计访问频率超过 probation 段尾部（也就是最
+// 接近被淘汰）的 key 时才会被正式准入主缓存，否则这个被挤出窗口的 key 自己就会
+// 被淘汰——这样可以避免偶发的一次性访问把真正的热点 key 挤出去。
+type TinyLFU struct {
+	mu sync.Mutex
+
+	window    *list.List
+	windowM   map[string]*list.Element
+	windowCap int
+
+	probation    *list.List
+	probationM   map[string]*list.Element
+	protected    *list.List
+	protectedM   map[string]*list.Element
+	mainCap      int
+	protectedCap int
+
+	sketch       *countMinSketch
+	inserts      int
+	ageThreshold int
+
+	// pending 保存已经决出、但还没被 Evict() 取走的淘汰候选
+	pending []string
+}
+
+// NewTinyLFU 返回一个容量上限约为 capacity 个 key 的 TinyLFU 策略。capacity 只是
+// 给内部窗口/主缓存分段和 Count-Min Sketch 宽度定尺寸用的估计值，真正的容量上限
+// 由 NewWithPolicy 的 maxItems 决定。
+func NewTinyLFU(capacity int) *TinyLFU {
+	if capacity < 1 {
+		capacity = 1
+	}
+	windowCap := capacity / 100
+	if windowCap < 1 {
+		windowCap = 1
+	}
+	mainCap := capacity - windowCap
+	if mainCap < 1 {
+		mainCap = 1
+	}
+	protectedCap := mainCap * 4 / 5
+	if protectedCap < 1 {
+		protectedCap = 1
+	}
+	return &TinyLFU{
+		window:       list.New(),
+		windowM:      make(map[string]*list.Element),
+		windowCap:    windowCap,
+		probation:    list.New(),
+		probationM:   make(map[string]*list.Element),
+		protected:    list.New(),
+		protectedM:   make(map[string]*list.Element),
+		mainCap:      mainCap,
+		protectedCap: protectedCap,
+		sketch:       newCountMinSketch(capacity * 8),
+		ageThreshold: capacity * 10,
+	}
+}
+
+func (p *TinyLFU) maybeAge() {
+	p.inserts++
+	if p.inserts >= p.ageThreshold {
+		p.sketch.age()
+		p.inserts = 0
+	}
+}
+
+// RecordAccess 实现 EvictionPolicy：窗口里的 key 移到窗口头部；probation 段的
+// key 说明最近又被用到了，晋升到 protected 段（如果 protected 段因此超员，把它
+// 尾部最久没访问的 key 降级回 probation 段头部）；protected 段的 key 移到头部。
+// 如果 key 已经被 admit 判定淘汰、正躺在 pending 里等 Evict() 取走，说明判定已经
+// 过时了——把它从 pending 里救回来，按 admit 的规则重新决出一次准入结果，避免一个
+// condemned 的 key 在真正被淘汰前，无论被访问多少次都无法被救回来。
+func (p *TinyLFU) RecordAccess(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sketch.add(key)
+
+	if e, ok := p.windowM[key]; ok {
+		p.window.MoveToFront(e)
+		return
+	}
+	if e, ok := p.probationM[key]; ok {
+		p.probation.Remove(e)
+		delete(p.probationM, key)
+		ne := p.protected.PushFront(key)
+		p.protectedM[key] = ne
+		if p.protected.Len() > p.protectedCap {
+			back := p.protected.Back()
+			demoted := back.Value.(string)
+			p.protected.Remove(back)
+			delete(p.protectedM, demoted)
+			de := p.probation.PushFront(demoted)
+			p.probationM[demoted] = de
+		}
+		return
+	}
+	if e, ok := p.protectedM[key]; ok {
+		p.protected.MoveToFront(e)
+		return
+	}
+	for i, k := range p.pending {
+		if k == key {
+			p.pending = append(p.pending[:i], p.pending[i+1:]...)
+			p.admit(key)
+			return
+		}
+	}
+}
+
+// RecordInsert 实现 EvictionPolicy：新 key 先进窗口；窗口满了就把尾部 key 挤出
+// 来尝试准入主缓存。
+func (p *TinyLFU) RecordInsert(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sketch.add(key)
+	p.maybeAge()
+
+	if e, ok := p.windowM[key]; ok {
+		p.window.MoveToFront(e)
+		return
+	}
+	if _, ok := p.probationM[key]; ok {
+		return
+	}
+	if _, ok := p.protectedM[key]; ok {
+		return
+	}
+
+	p.windowM[key] = p.window.PushFront(key)
+	for p.window.Len() > p.windowCap {
+		back := p.window.Back()
+		candidate := back.Value.(string)
+		p.window.Remove(back)
+		delete(p.windowM, candidate)
+		p.admit(candidate)
+	}
+}
+
+// admit 决定一个从窗口里挤出来的 candidate 能不能进入主缓存的 probation 段：主
+// 缓存没满就直接放进去；满了就和 probation 尾部的受害者比较 Count-Min Sketch
+// 估计的频率，频率更高的一个留下，另一个被记录为下一次 Evict() 的淘汰目标。
+func (p *TinyLFU) admit(candidate string) {
+	if p.probation.Len()+p.protected.Len() < p.mainCap {
+		p.probationM[candidate] = p.probation.PushFront(candidate)
+		return
+	}
+	back := p.probation.Back()
+	if back == nil {
+		// probation 段是空的，说明 main 全部挤在 protected 段里，直接丢弃 candidate
+		p.pending = append(p.pending, candidate)
+		return
+	}
+	victim := back.Value.(string)
+	if p.sketch.estimate(candidate) > p.sketch.estimate(victim) {
+		p.probation.Remove(back)
+		delete(p.probationM, victim)
+		p.probationM[candidate] = p.probation.PushFront(candidate)
+		p.pending = append(p.pending, victim)
+	} else {
+		p.pending = append(p.pending, candidate)
+	}
+}
+
+// Evict 实现 EvictionPolicy
+func (p *TinyLFU) Evict() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.pending) > 0 {
+		key := p.pending[0]
+		p.pending = p.pending[1:]
+		return key, true
+	}
+	// 正常情况下窗口/主缓存的容量总和约等于 cache 的 MaxItems，不会走到这里；
+	// 兜底一下，按 probation -> protected -> window 的顺序淘汰最老的 key
+	if back := p.probation.Back(); back != nil {
+		key := back.Value.(string)
+		p.probation.Remove(back)
+		delete(p.probationM, key)
+		return key, true
+	}
+	if back := p.protected.Back(); back != nil {
+		key := back.Value.(string)
+		p.protected.Remove(back)
+		delete(p.protectedM, key)
+		return key, true
+	}
+	if back := p.window.Back(); back != nil {
+		key := back.Value.(string)
+		p.window.Remove(back)
+		delete(p.windowM, key)
+		return key, true
+	}
+	return "", false
+}
+
+// Remove 实现 EvictionPolicy
+func (p *TinyLFU) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.windowM[key]; ok {
+		p.window.Remove(e)
+		delete(p.windowM, key)
+		return
+	}
+	if e, ok := p.probationM[key]; ok {
+		p.probation.Remove(e)
+		delete(p.probationM, key)
+		return
+	}
+	if e, ok := p.protectedM[key]; ok {
+		p.protected.Remove(e)
+		delete(p.protectedM, key)
+		return
+	}
+	for i, k := range p.pending {
+		if k == key {
+			p.pending = append(p.pending[:i], p.pending[i+1:]...)
+			return
+		}
+	}
+}